@@ -0,0 +1,151 @@
+package main
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	_ "modernc.org/sqlite"
+)
+
+// EmbeddingCache stores previously computed sentence embeddings so repeated
+// ingestion of the same or overlapping corpora doesn't re-run GPU inference.
+type EmbeddingCache interface {
+	// Get returns the cached embedding and token count for (modelID, text), if present.
+	Get(modelID, text string) (embedding []float32, tokenCount int, ok bool)
+	// Put writes through a freshly computed embedding.
+	Put(modelID, text string, embedding []float32, tokenCount int) error
+	// Stats reports current cache occupancy.
+	Stats() (CacheStats, error)
+	// Purge removes all cached entries.
+	Purge() error
+	// Close releases any underlying resources.
+	Close() error
+}
+
+// CacheStats summarizes the current state of an EmbeddingCache.
+type CacheStats struct {
+	Entries  int64 `json:"entries"`
+	Bytes    int64 `json:"bytes"`
+	MaxBytes int64 `json:"max_bytes"`
+}
+
+// SQLiteCache is an EmbeddingCache backed by a SQLite database file, keyed on
+// (model_id, normalized_text_hash).
+type SQLiteCache struct {
+	db    *sql.DB
+	maxMB int
+	mu    sync.Mutex
+}
+
+// NewSQLiteCache opens (creating if necessary) a SQLite-backed embedding
+// cache at path. maxMB is a soft bound reported via Stats (see
+// CacheStats.MaxBytes) for callers or operators to act on; it is not
+// enforced here and nothing is evicted once it's exceeded. A value of 0
+// means unbounded.
+func NewSQLiteCache(path string, maxMB int) (*SQLiteCache, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache db: %w", err)
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS embeddings (
+		model_id    TEXT NOT NULL,
+		text_hash   TEXT NOT NULL,
+		embedding   BLOB NOT NULL,
+		token_count INTEGER NOT NULL,
+		PRIMARY KEY (model_id, text_hash)
+	);`
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize cache schema: %w", err)
+	}
+
+	return &SQLiteCache{db: db, maxMB: maxMB}, nil
+}
+
+func (c *SQLiteCache) Get(modelID, text string) ([]float32, int, bool) {
+	hash := normalizedTextHash(text)
+
+	var blob []byte
+	var tokenCount int
+	row := c.db.QueryRow(
+		`SELECT embedding, token_count FROM embeddings WHERE model_id = ? AND text_hash = ?`,
+		modelID, hash,
+	)
+	if err := row.Scan(&blob, &tokenCount); err != nil {
+		return nil, 0, false
+	}
+
+	return decodeEmbedding(blob), tokenCount, true
+}
+
+func (c *SQLiteCache) Put(modelID, text string, embedding []float32, tokenCount int) error {
+	hash := normalizedTextHash(text)
+	blob := encodeEmbedding(embedding)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec(
+		`INSERT OR REPLACE INTO embeddings (model_id, text_hash, embedding, token_count) VALUES (?, ?, ?, ?)`,
+		modelID, hash, blob, tokenCount,
+	)
+	return err
+}
+
+func (c *SQLiteCache) Stats() (CacheStats, error) {
+	var entries, bytes int64
+	row := c.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(LENGTH(embedding)), 0) FROM embeddings`)
+	if err := row.Scan(&entries, &bytes); err != nil {
+		return CacheStats{}, err
+	}
+
+	return CacheStats{
+		Entries:  entries,
+		Bytes:    bytes,
+		MaxBytes: int64(c.maxMB) * 1024 * 1024,
+	}, nil
+}
+
+func (c *SQLiteCache) Purge() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, err := c.db.Exec(`DELETE FROM embeddings`)
+	return err
+}
+
+func (c *SQLiteCache) Close() error {
+	return c.db.Close()
+}
+
+// normalizedTextHash hashes the normalized form of text (leading/trailing
+// whitespace trimmed, interior runs of whitespace collapsed to a single
+// space) so minor formatting differences don't cause spurious cache misses.
+func normalizedTextHash(text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	sum := sha256.Sum256([]byte(normalized))
+	return fmt.Sprintf("%x", sum)
+}
+
+func encodeEmbedding(embedding []float32) []byte {
+	buf := make([]byte, 4*len(embedding))
+	for i, f := range embedding {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(f))
+	}
+	return buf
+}
+
+func decodeEmbedding(blob []byte) []float32 {
+	embedding := make([]float32, len(blob)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(blob[i*4:]))
+	}
+	return embedding
+}
@@ -23,7 +23,10 @@ type ChunkingConfig struct {
 
 // EmbeddingConfig holds embedding model configuration
 type EmbeddingConfig struct {
-	MaxBatchTokens int // Max total tokens per batch (controls GPU memory usage)
+	MaxBatchTokens int    // Max total tokens per batch (controls GPU memory usage)
+	ModelID        string // Identifies the loaded model; part of the cache key
+	CachePath      string // Path to the SQLite embedding cache; empty disables caching
+	CacheMaxMB     int    // Soft size bound (in MB) for the embedding cache
 }
 
 // LoadServerConfig loads server configuration from environment variables
@@ -60,6 +63,9 @@ func LoadServerConfig() ServerConfig {
 // Falls back to defaults if not set
 func LoadEmbeddingConfig() EmbeddingConfig {
 	maxBatchTokens := 6000 // default
+	modelID := "default"   // default
+	cachePath := ""        // caching disabled by default
+	cacheMaxMB := 512
 
 	if envVal := os.Getenv("MAX_BATCH_TOKENS"); envVal != "" {
 		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
@@ -67,8 +73,25 @@ func LoadEmbeddingConfig() EmbeddingConfig {
 		}
 	}
 
+	if envVal := os.Getenv("MODEL_ID"); envVal != "" {
+		modelID = envVal
+	}
+
+	if envVal := os.Getenv("CACHE_PATH"); envVal != "" {
+		cachePath = envVal
+	}
+
+	if envVal := os.Getenv("CACHE_MAX_MB"); envVal != "" {
+		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
+			cacheMaxMB = val
+		}
+	}
+
 	return EmbeddingConfig{
 		MaxBatchTokens: maxBatchTokens,
+		ModelID:        modelID,
+		CachePath:      cachePath,
+		CacheMaxMB:     cacheMaxMB,
 	}
 }
 
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/IBM/sarama"
+)
+
+// KafkaConfig holds configuration for the optional Kafka ingestion worker.
+type KafkaConfig struct {
+	Brokers     []string
+	InputTopic  string
+	OutputTopic string
+	GroupID     string
+}
+
+// LoadKafkaConfig loads Kafka worker configuration from environment variables.
+func LoadKafkaConfig() KafkaConfig {
+	brokers := []string{"localhost:9092"}
+	if envVal := getEnv("KAFKA_BROKERS", ""); envVal != "" {
+		brokers = strings.Split(envVal, ",")
+	}
+
+	return KafkaConfig{
+		Brokers:     brokers,
+		InputTopic:  getEnv("KAFKA_INPUT_TOPIC", "embed-requests"),
+		OutputTopic: getEnv("KAFKA_OUTPUT_TOPIC", "embed-responses"),
+		GroupID:     getEnv("KAFKA_GROUP_ID", "go-semantic-chunking"),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return fallback
+}
+
+// RunKafkaWorker consumes EmbedRequest messages from cfg.InputTopic. Several
+// in-flight messages are coalesced into a single GPU-batched sentence
+// embedding call (honoring embeddingConfig.MaxBatchTokens), after which each
+// document is chunked, its chunks embedded, and a DocumentResponse published
+// to cfg.OutputTopic. A message that is not valid EmbedRequest JSON is marked
+// and committed at once, with no response published. Any other message's
+// offset is only marked and committed once its DocumentResponse has actually
+// been published; a failure of the coalesced embed call or of the publish
+// leaves it (and everything after it in the batch) unmarked so
+// it's redelivered on the next poll, giving at-least-once semantics.
+func RunKafkaWorker(ctx context.Context, cfg KafkaConfig, embeddingModel *EmbeddingModel, embeddingConfig EmbeddingConfig) error {
+	saramaConfig := sarama.NewConfig()
+	saramaConfig.Version = sarama.V2_8_0_0
+	saramaConfig.Consumer.Offsets.AutoCommit.Enable = false
+	saramaConfig.Consumer.Return.Errors = true
+	saramaConfig.Producer.Return.Successes = true
+	saramaConfig.Producer.RequiredAcks = sarama.WaitForAll
+
+	producer, err := sarama.NewSyncProducer(cfg.Brokers, saramaConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kafka producer: %w", err)
+	}
+	defer producer.Close()
+
+	consumerGroup, err := sarama.NewConsumerGroup(cfg.Brokers, cfg.GroupID, saramaConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kafka consumer group: %w", err)
+	}
+	defer consumerGroup.Close()
+
+	handler := &kafkaHandler{
+		embeddingModel:  embeddingModel,
+		embeddingConfig: embeddingConfig,
+		producer:        producer,
+		outputTopic:     cfg.OutputTopic,
+	}
+
+	go func() {
+		for err := range consumerGroup.Errors() {
+			log.Printf("Kafka consumer group error: %v", err)
+		}
+	}()
+
+	for {
+		if err := consumerGroup.Consume(ctx, []string{cfg.InputTopic}, handler); err != nil {
+			return fmt.Errorf("kafka consume session ended: %w", err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// kafkaHandler implements sarama.ConsumerGroupHandler. It coalesces incoming
+// messages into a batch that is flushed once its sentences reach
+// embeddingConfig.MaxBatchTokens tokens (so the last message added can take
+// it past that count), embeds all
+// of their sentences in a single EmbedSentences call, then chunks, embeds,
+// and publishes each document individually.
+type kafkaHandler struct {
+	embeddingModel  *EmbeddingModel
+	embeddingConfig EmbeddingConfig
+	producer        sarama.SyncProducer
+	outputTopic     string
+}
+
+func (h *kafkaHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *kafkaHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+// kafkaBatchItem pairs a raw message with its already-decoded request,
+// already-extracted sentences, and resolved chunking config, so nothing
+// about the message is parsed or segmented twice later in the batch.
+type kafkaBatchItem struct {
+	msg            *sarama.ConsumerMessage
+	req            *EmbedRequest
+	sentences      []*Sentence
+	chunkingConfig ChunkingConfig
+}
+
+func (h *kafkaHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	var batch []kafkaBatchItem
+	batchTokens := 0
+
+	flush := func() {
+		h.processAndPublishBatch(session, batch)
+		batch = nil
+		batchTokens = 0
+	}
+
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				flush()
+				return nil
+			}
+
+			var req EmbedRequest
+			if err := json.Unmarshal(msg.Value, &req); err != nil {
+				log.Printf("Failed to decode EmbedRequest from kafka message: %v", err)
+				session.MarkMessage(msg, "")
+				session.Commit()
+				continue
+			}
+
+			chunkingConfig := DefaultChunkingConfig()
+			if req.ChunkingConfig != nil {
+				chunkingConfig = *req.ChunkingConfig
+			}
+
+			var sentences []*Sentence
+			if req.Text != "" {
+				frames := []Frame{{Text: req.Text, StartTime: "", EndTime: ""}}
+				segmenter := SegmenterForName(req.Segmenter)
+				sentences = h.embeddingModel.ExtractSentencesFromFramesWithSegmenter(frames, chunkingConfig.MaxSize, segmenter)
+			}
+
+			batch = append(batch, kafkaBatchItem{msg: msg, req: &req, sentences: sentences, chunkingConfig: chunkingConfig})
+			for _, s := range sentences {
+				batchTokens += s.TokenCount
+			}
+			if batchTokens >= h.embeddingConfig.MaxBatchTokens {
+				flush()
+			}
+		case <-session.Context().Done():
+			flush()
+			return nil
+		}
+	}
+}
+
+// processAndPublishBatch embeds all of batch's already-extracted sentences in
+// a single coalesced GPU call, then chunks, embeds, and publishes each
+// document in order. A document-level failure (bad input, chunking/embedding
+// error) is reported via DocumentResponse.Error and still committed, matching
+// how the HTTP path reports per-document errors. Only an infra-level failure
+// — the coalesced embed call itself, or publishing a response to Kafka —
+// leaves that message and the rest of the batch unmarked so they're
+// redelivered. Every message that gets marked is committed together in one
+// Commit() call at the end of the batch rather than one round trip per
+// message.
+func (h *kafkaHandler) processAndPublishBatch(session sarama.ConsumerGroupSession, batch []kafkaBatchItem) {
+	if len(batch) == 0 {
+		return
+	}
+
+	// Concatenate every document's sentences into one slice so EmbedSentences
+	// below sees the whole batch at once, rather than one GPU call per
+	// document.
+	var allSentences []*Sentence
+	bounds := make([]int, len(batch)+1)
+	for i, item := range batch {
+		bounds[i] = len(allSentences)
+		allSentences = append(allSentences, item.sentences...)
+	}
+	bounds[len(batch)] = len(allSentences)
+
+	if len(allSentences) > 0 {
+		if err := h.embeddingModel.EmbedSentences(allSentences); err != nil {
+			log.Printf("Failed to embed coalesced kafka batch of %d sentences: %v", len(allSentences), err)
+			return // infra failure; nothing in this batch is marked, all of it is redelivered
+		}
+	}
+
+	for i, item := range batch {
+		if !h.publishDocument(item.req, allSentences[bounds[i]:bounds[i+1]], item.chunkingConfig) {
+			break // infra failure; stop marking, this and later messages are redelivered
+		}
+		session.MarkMessage(item.msg, "")
+	}
+	session.Commit()
+}
+
+// publishDocument builds a DocumentResponse for req from its already-embedded
+// sentences and publishes it to Kafka. Document-level problems (empty text,
+// a chunking or embedding error) are recorded in DocumentResponse.Error, the
+// same way processDocument reports them over HTTP, and still count as
+// published. Only a failure to encode or publish the response itself — which
+// a retry might fix — returns false.
+func (h *kafkaHandler) publishDocument(req *EmbedRequest, sentences []*Sentence, chunkingConfig ChunkingConfig) bool {
+	docResp := DocumentResponse{ID: req.ID}
+
+	switch {
+	case req.Text == "":
+		docResp.Error = "Text field is required"
+	case len(sentences) == 0:
+		docResp.Chunks = []ChunkResponse{}
+	default:
+		chunks, err := chunkingConfig.ExtractChunksFromSentences(sentences)
+		if err != nil {
+			docResp.Error = fmt.Sprintf("Processing failed: %v", err)
+			break
+		}
+
+		if err := h.embeddingModel.EmbedChunks(chunks); err != nil {
+			docResp.Error = fmt.Sprintf("Processing failed: %v", err)
+			break
+		}
+
+		docResp.Chunks = make([]ChunkResponse, len(chunks))
+		for i, chunk := range chunks {
+			docResp.Chunks[i] = ChunkResponse{
+				Text:         chunk.Text,
+				StartTime:    chunk.StartTime,
+				Embedding:    chunk.Embedding,
+				NumSentences: chunk.NumSentences,
+				TokenCount:   chunk.TokenCount,
+				ChunkIndex:   chunk.ChunkIndex,
+			}
+		}
+	}
+
+	payload, err := json.Marshal(docResp)
+	if err != nil {
+		log.Printf("Failed to encode DocumentResponse for kafka publish: %v", err)
+		return false
+	}
+
+	if _, _, err := h.producer.SendMessage(&sarama.ProducerMessage{
+		Topic: h.outputTopic,
+		Key:   sarama.StringEncoder(docResp.ID),
+		Value: sarama.ByteEncoder(payload),
+	}); err != nil {
+		log.Printf("Failed to publish DocumentResponse to kafka: %v", err)
+		return false
+	}
+
+	return true
+}
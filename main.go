@@ -1,12 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 )
 
@@ -15,6 +17,10 @@ type EmbedRequest struct {
 	ID             string          `json:"id,omitempty"`
 	Text           string          `json:"text"`
 	ChunkingConfig *ChunkingConfig `json:"chunking_config,omitempty"`
+	// Segmenter selects the sentence boundary detector: "default" (abbreviation-
+	// and Unicode-aware, the default), "aggressive" (naive terminator split),
+	// or "pysbd-like". See segmenter.go.
+	Segmenter string `json:"segmenter,omitempty"`
 }
 
 // BatchEmbedRequest represents the HTTP request body supporting both single and batch
@@ -44,6 +50,15 @@ type BatchEmbedResponse struct {
 	Documents []DocumentResponse `json:"documents"`
 }
 
+// StreamEvent represents a single Server-Sent Event emitted by the streaming
+// variant of /embed. Event is one of "chunk", "done", "error", or "end".
+type StreamEvent struct {
+	Event      string         `json:"event"`
+	DocumentID string         `json:"document_id,omitempty"`
+	Chunk      *ChunkResponse `json:"chunk,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
 func main() {
 	// Load server and embedding configurations from environment variables
 	serverConfig := LoadServerConfig()
@@ -61,11 +76,56 @@ func main() {
 	defer embeddingModel.Close()
 	fmt.Println("Embedding model loaded successfully")
 
+	// Open the embedding cache, if configured. processText consults it before
+	// enqueueing sentences for GPU inference and writes through on miss.
+	var embeddingCache EmbeddingCache
+	if embeddingConfig.CachePath != "" {
+		sqliteCache, err := NewSQLiteCache(embeddingConfig.CachePath, embeddingConfig.CacheMaxMB)
+		if err != nil {
+			log.Fatalf("Failed to open embedding cache: %v", err)
+		}
+		defer sqliteCache.Close()
+		embeddingCache = sqliteCache
+		fmt.Printf("Embedding cache opened at %s (max %dMB)\n", embeddingConfig.CachePath, embeddingConfig.CacheMaxMB)
+	}
+
 	// Create and start HTTP server
 	mux := http.NewServeMux()
 	mux.HandleFunc("/embed", func(w http.ResponseWriter, r *http.Request) {
-		handleEmbed(w, r, embeddingModel)
+		handleEmbed(w, r, embeddingModel, embeddingCache, embeddingConfig.ModelID)
+	})
+	mux.HandleFunc("/cache/stats", func(w http.ResponseWriter, r *http.Request) {
+		handleCacheStats(w, r, embeddingCache)
+	})
+	mux.HandleFunc("/cache/purge", func(w http.ResponseWriter, r *http.Request) {
+		handleCachePurge(w, r, embeddingCache)
+	})
+
+	// Resumable chunked upload API: clients too large for a single POST body
+	// open a session, append ordered text fragments, then complete it.
+	sessionStore := NewSessionStore(LoadSessionConfig())
+	mux.HandleFunc("/embed/session", func(w http.ResponseWriter, r *http.Request) {
+		handleCreateSession(w, r, sessionStore)
+	})
+	mux.HandleFunc("/embed/session/", func(w http.ResponseWriter, r *http.Request) {
+		handleSessionSub(w, r, sessionStore, embeddingModel, embeddingCache, embeddingConfig.ModelID)
 	})
+
+	// MODE=kafka additionally runs a Kafka consumer/producer worker so the
+	// service can slot into streaming data pipelines without an HTTP proxy
+	// in front of it.
+	kafkaCtx, stopKafka := context.WithCancel(context.Background())
+	defer stopKafka()
+	if os.Getenv("MODE") == "kafka" {
+		kafkaConfig := LoadKafkaConfig()
+		fmt.Printf("Starting Kafka worker: brokers=%v input=%s output=%s group=%s\n",
+			kafkaConfig.Brokers, kafkaConfig.InputTopic, kafkaConfig.OutputTopic, kafkaConfig.GroupID)
+		go func() {
+			if err := RunKafkaWorker(kafkaCtx, kafkaConfig, embeddingModel, embeddingConfig); err != nil && kafkaCtx.Err() == nil {
+				log.Fatalf("Kafka worker failed: %v", err)
+			}
+		}()
+	}
 	server := &http.Server{
 		Addr:         ":" + serverConfig.Port,
 		Handler:      mux,
@@ -87,7 +147,7 @@ func main() {
 }
 
 // handleEmbed processes a BatchEmbedRequest
-func handleEmbed(w http.ResponseWriter, r *http.Request, embeddingModel *EmbeddingModel) {
+func handleEmbed(w http.ResponseWriter, r *http.Request, embeddingModel *EmbeddingModel, cache EmbeddingCache, modelID string) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
@@ -104,12 +164,17 @@ func handleEmbed(w http.ResponseWriter, r *http.Request, embeddingModel *Embeddi
 		return
 	}
 
+	if wantsStream(r) {
+		streamEmbed(w, r, embeddingModel, batchReq, cache, modelID)
+		return
+	}
+
 	// Process each document
 	response := BatchEmbedResponse{
 		Documents: make([]DocumentResponse, len(batchReq.Documents)),
 	}
 	for i, doc := range batchReq.Documents {
-		docResp := processDocument(embeddingModel, &doc)
+		docResp := processDocument(embeddingModel, &doc, cache, modelID, nil)
 		response.Documents[i] = docResp
 	}
 
@@ -120,8 +185,108 @@ func handleEmbed(w http.ResponseWriter, r *http.Request, embeddingModel *Embeddi
 	}
 }
 
-// processDocument processes a single document and returns its response
-func processDocument(embeddingModel *EmbeddingModel, doc *EmbedRequest) DocumentResponse {
+// handleCacheStats reports embedding cache occupancy.
+func handleCacheStats(w http.ResponseWriter, r *http.Request, cache EmbeddingCache) {
+	if cache == nil {
+		http.Error(w, "Embedding cache is not enabled", http.StatusNotFound)
+		return
+	}
+
+	stats, err := cache.Stats()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Failed to read cache stats: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		log.Printf("Failed to encode cache stats: %v", err)
+	}
+}
+
+// handleCachePurge clears all entries from the embedding cache.
+func handleCachePurge(w http.ResponseWriter, r *http.Request, cache EmbeddingCache) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if cache == nil {
+		http.Error(w, "Embedding cache is not enabled", http.StatusNotFound)
+		return
+	}
+
+	if err := cache.Purge(); err != nil {
+		http.Error(w, fmt.Sprintf("Failed to purge cache: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// wantsStream reports whether the client asked for an incremental response,
+// either via the SSE Accept header or the ?stream=1 query parameter.
+func wantsStream(r *http.Request) bool {
+	if r.URL.Query().Get("stream") == "1" {
+		return true
+	}
+	for _, accept := range r.Header["Accept"] {
+		if strings.Contains(accept, "text/event-stream") {
+			return true
+		}
+	}
+	return false
+}
+
+// streamEmbed processes each document in turn, emitting a "chunk" event for
+// each of a document's chunks once that document's chunks are embedded, a
+// "done" event once a document finishes, and a final "end" event once the
+// whole batch completes. This lets a client begin indexing embeddings while
+// later documents are still being computed. Within one document, chunk
+// events are not sent until every chunk of that document has been embedded.
+func streamEmbed(w http.ResponseWriter, r *http.Request, embeddingModel *EmbeddingModel, batchReq BatchEmbedRequest, cache EmbeddingCache, modelID string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	enc := json.NewEncoder(w)
+	writeEvent := func(ev StreamEvent) {
+		fmt.Fprint(w, "event: ", ev.Event, "\n", "data: ")
+		if err := enc.Encode(ev); err != nil {
+			log.Printf("Failed to encode stream event: %v", err)
+			return
+		}
+		fmt.Fprint(w, "\n")
+		flusher.Flush()
+	}
+
+	for _, doc := range batchReq.Documents {
+		docID := doc.ID
+		docResp := processDocument(embeddingModel, &doc, cache, modelID, func(chunk ChunkResponse) {
+			writeEvent(StreamEvent{Event: "chunk", DocumentID: docID, Chunk: &chunk})
+		})
+		if docResp.Error != "" {
+			writeEvent(StreamEvent{Event: "error", DocumentID: docID, Error: docResp.Error})
+			continue
+		}
+		writeEvent(StreamEvent{Event: "done", DocumentID: docID})
+	}
+
+	writeEvent(StreamEvent{Event: "end"})
+}
+
+// processDocument processes a single document and returns its response.
+// If onChunk is non-nil, it is invoked with each chunk as soon as it is
+// produced, allowing callers to stream results incrementally. cache may be
+// nil, in which case sentences are always sent to the embedding model.
+func processDocument(embeddingModel *EmbeddingModel, doc *EmbedRequest, cache EmbeddingCache, modelID string, onChunk func(ChunkResponse)) DocumentResponse {
 	resp := DocumentResponse{
 		ID: doc.ID,
 	}
@@ -138,8 +303,10 @@ func processDocument(embeddingModel *EmbeddingModel, doc *EmbedRequest) Document
 		chunkingConfig = *doc.ChunkingConfig
 	}
 
+	segmenter := SegmenterForName(doc.Segmenter)
+
 	// Process the text
-	chunks, err := processText(embeddingModel, doc.Text, chunkingConfig)
+	chunks, err := processText(embeddingModel, doc.Text, chunkingConfig, segmenter, cache, modelID, onChunk)
 	if err != nil {
 		resp.Error = fmt.Sprintf("Processing failed: %v", err)
 		return resp
@@ -161,23 +328,27 @@ func processDocument(embeddingModel *EmbeddingModel, doc *EmbedRequest) Document
 	return resp
 }
 
-// processText takes raw text and returns semantic chunks with embeddings
-func processText(embeddingModel *EmbeddingModel, text string, chunkingConfig ChunkingConfig) ([]*Chunk, error) {
+// processText takes raw text and returns semantic chunks with embeddings.
+// If onChunk is non-nil, it is called once per chunk, in order, after the
+// chunks' embeddings are ready but before the full slice is returned to the
+// caller — this is what lets streamEmbed flush per-chunk events instead of
+// waiting for every document in the batch to finish. cache may be nil, in
+// which case every sentence goes straight to the embedding model.
+func processText(embeddingModel *EmbeddingModel, text string, chunkingConfig ChunkingConfig, segmenter SentenceSegmenter, cache EmbeddingCache, modelID string, onChunk func(ChunkResponse)) ([]*Chunk, error) {
 	log.Printf("Processing text (%d characters)", len(text))
 
 	// Create a single "frame" from the input text
 	frames := []Frame{{Text: text, StartTime: "", EndTime: ""}}
 
 	// Extract sentences from the text
-	sentences := embeddingModel.ExtractSentencesFromFrames(frames)
+	sentences := embeddingModel.ExtractSentencesFromFramesWithSegmenter(frames, chunkingConfig.MaxSize, segmenter)
 	log.Printf("Extracted %d sentences", len(sentences))
 
 	if len(sentences) == 0 {
 		return []*Chunk{}, nil
 	}
 
-	// Embed sentences
-	if err := embeddingModel.EmbedSentences(sentences); err != nil {
+	if err := embedSentencesWithCache(embeddingModel, sentences, cache, modelID); err != nil {
 		return nil, fmt.Errorf("failed to embed sentences: %w", err)
 	}
 	log.Printf("Embedded %d sentences", len(sentences))
@@ -195,5 +366,57 @@ func processText(embeddingModel *EmbeddingModel, text string, chunkingConfig Chu
 	}
 	log.Printf("Embedded %d chunks", len(chunks))
 
+	// Hand each chunk to onChunk now that all of this document's chunk
+	// embeddings are ready, rather than making the caller wait for the rest
+	// of the batch.
+	if onChunk != nil {
+		for _, chunk := range chunks {
+			onChunk(ChunkResponse{
+				Text:         chunk.Text,
+				StartTime:    chunk.StartTime,
+				Embedding:    chunk.Embedding,
+				NumSentences: chunk.NumSentences,
+				TokenCount:   chunk.TokenCount,
+				ChunkIndex:   chunk.ChunkIndex,
+			})
+		}
+	}
+
 	return chunks, nil
 }
+
+// embedSentencesWithCache populates sent.Embedding for each sentence,
+// consulting cache first and only sending cache misses to the embedding
+// model. Freshly computed embeddings are written through to the cache. If
+// cache is nil, every sentence is sent to the embedding model unconditionally.
+func embedSentencesWithCache(embeddingModel *EmbeddingModel, sentences []*Sentence, cache EmbeddingCache, modelID string) error {
+	if cache == nil {
+		return embeddingModel.EmbedSentences(sentences)
+	}
+
+	var misses []*Sentence
+	for _, sent := range sentences {
+		if embedding, tokenCount, ok := cache.Get(modelID, sent.Text); ok {
+			sent.Embedding = embedding
+			sent.TokenCount = tokenCount
+			continue
+		}
+		misses = append(misses, sent)
+	}
+
+	if len(misses) == 0 {
+		return nil
+	}
+
+	if err := embeddingModel.EmbedSentences(misses); err != nil {
+		return err
+	}
+
+	for _, sent := range misses {
+		if err := cache.Put(modelID, sent.Text, sent.Embedding, sent.TokenCount); err != nil {
+			log.Printf("Failed to write through embedding cache: %v", err)
+		}
+	}
+
+	return nil
+}
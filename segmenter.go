@@ -0,0 +1,194 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// SentenceSegmenter splits raw text into sentence strings.
+type SentenceSegmenter interface {
+	Segment(text string) []string
+}
+
+// DefaultAbbreviations is the built-in list of tokens that, even though they
+// end in '.', are not treated as a sentence boundary by RuleBasedSegmenter.
+var DefaultAbbreviations = []string{
+	"Dr", "Mr", "Mrs", "Ms", "Prof", "St", "vs", "etc", "e.g", "i.e",
+	"Jr", "Sr", "Gen", "Rep", "Sen", "Gov", "Lt", "Col", "Capt", "Cmdr",
+	"Ph.D", "M.D", "B.A", "M.A", "Inc", "Ltd", "Co", "Corp",
+}
+
+// trailingClosers are punctuation runs that can trail a terminator
+// ("hello." 'she said.') without affecting boundary detection.
+const trailingClosers = `"'”’)]`
+
+// RuleBasedSegmenter is the default SentenceSegmenter. It splits on '.', '?',
+// '!', and unicode ellipsis while protecting a configurable abbreviation
+// list, decimal/numeric continuations, and quoted or bracketed terminators.
+type RuleBasedSegmenter struct {
+	Abbreviations map[string]bool
+	UnicodeAware  bool // use unicode.IsLower/IsDigit instead of ASCII-only checks
+	Aggressive    bool // skip abbreviation/decimal protection; split on any terminator
+}
+
+// NewDefaultSegmenter returns the standard abbreviation-aware, Unicode-aware
+// segmenter used when no "segmenter" is specified on a request.
+func NewDefaultSegmenter() *RuleBasedSegmenter {
+	return &RuleBasedSegmenter{
+		Abbreviations: abbreviationSet(DefaultAbbreviations),
+		UnicodeAware:  true,
+	}
+}
+
+// NewAggressiveSegmenter returns a segmenter that splits after any word ending
+// in `.`, `?`, `!`, or `…` (ignoring trailing quotes/brackets), with no
+// abbreviation, numeric, or next-word checks. Useful when
+// text is known to be free of abbreviations and raw throughput matters more
+// than boundary accuracy.
+func NewAggressiveSegmenter() *RuleBasedSegmenter {
+	return &RuleBasedSegmenter{
+		Abbreviations: map[string]bool{},
+		Aggressive:    true,
+	}
+}
+
+// NewPysbdLikeSegmenter returns the abbreviation- and Unicode-aware
+// segmenter, mirroring the rule-based heuristics used by segmenters like
+// pysbd (abbreviation lists, decimal/list-numbering guards, quote handling).
+func NewPysbdLikeSegmenter() *RuleBasedSegmenter {
+	return NewDefaultSegmenter()
+}
+
+// SegmenterForName resolves the "segmenter" field of an EmbedRequest to a
+// SentenceSegmenter, falling back to the default for an empty or unknown name.
+func SegmenterForName(name string) SentenceSegmenter {
+	switch name {
+	case "aggressive":
+		return NewAggressiveSegmenter()
+	case "pysbd-like":
+		return NewPysbdLikeSegmenter()
+	default:
+		return NewDefaultSegmenter()
+	}
+}
+
+func abbreviationSet(abbrevs []string) map[string]bool {
+	set := make(map[string]bool, len(abbrevs))
+	for _, a := range abbrevs {
+		set[strings.ToLower(a)] = true
+	}
+	return set
+}
+
+// Segment splits text into sentences on whitespace-delimited word boundaries.
+func (s *RuleBasedSegmenter) Segment(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	words := strings.Fields(text)
+	var sentences []string
+	var current strings.Builder
+
+	for i, word := range words {
+		if current.Len() > 0 {
+			current.WriteString(" ")
+		}
+		current.WriteString(word)
+
+		if !s.endsWithTerminator(word) {
+			continue
+		}
+
+		if !s.Aggressive {
+			if s.isProtectedAbbreviation(word) {
+				continue
+			}
+			if s.isNumericContinuation(word) {
+				continue
+			}
+			if !s.nextStartsNewSentence(words, i) {
+				continue
+			}
+		}
+
+		sentences = append(sentences, current.String())
+		current.Reset()
+	}
+
+	if current.Len() > 0 {
+		sentences = append(sentences, current.String())
+	}
+
+	return sentences
+}
+
+// endsWithTerminator reports whether word ends with a sentence terminator,
+// allowing trailing closing quotes/brackets and treating "...", "…", and
+// repeated "!?" runs as a single terminator.
+func (s *RuleBasedSegmenter) endsWithTerminator(word string) bool {
+	trimmed := strings.TrimRight(word, trailingClosers)
+	if trimmed == "" {
+		return false
+	}
+	last := []rune(trimmed)
+	r := last[len(last)-1]
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+// isProtectedAbbreviation reports whether the terminator-bearing word is
+// actually a protected abbreviation like "Dr." or "e.g.".
+func (s *RuleBasedSegmenter) isProtectedAbbreviation(word string) bool {
+	trimmed := strings.TrimRight(word, trailingClosers)
+	trimmed = strings.TrimRight(trimmed, ".!?…")
+	trimmed = strings.TrimLeft(trimmed, `"'“‘(`)
+	return s.Abbreviations[strings.ToLower(trimmed)]
+}
+
+// isNumericContinuation reports whether word is a bare number followed by a
+// period, e.g. a decimal ("3.") or list marker ("1."), rather than the end
+// of a sentence.
+func (s *RuleBasedSegmenter) isNumericContinuation(word string) bool {
+	trimmed := strings.TrimRight(word, trailingClosers)
+	trimmed = strings.TrimSuffix(trimmed, ".")
+	if trimmed == "" || trimmed == word {
+		return false
+	}
+	for _, r := range trimmed {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// nextStartsNewSentence reports whether the word following words[idx] looks
+// like the start of a new sentence. A lowercase or numeric first character
+// indicates the terminator was mid-sentence (an abbreviation or decimal we
+// didn't otherwise catch), so the boundary is suppressed.
+func (s *RuleBasedSegmenter) nextStartsNewSentence(words []string, idx int) bool {
+	if idx+1 >= len(words) {
+		return true
+	}
+
+	next := strings.TrimLeft(words[idx+1], `"'“‘(`)
+	if next == "" {
+		return true
+	}
+	first := []rune(next)[0]
+
+	if s.UnicodeAware {
+		if unicode.IsLower(first) || unicode.IsDigit(first) {
+			return false
+		}
+		return true
+	}
+
+	if first >= 'a' && first <= 'z' {
+		return false
+	}
+	if first >= '0' && first <= '9' {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRuleBasedSegmenterAbbreviations(t *testing.T) {
+	seg := NewDefaultSegmenter()
+
+	got := seg.Segment("Dr. Smith met Prof. Jones at the U.S. embassy. They left at noon.")
+	want := []string{
+		"Dr. Smith met Prof. Jones at the U.S. embassy.",
+		"They left at noon.",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Segment() = %#v, want %#v", got, want)
+	}
+}
+
+func TestRuleBasedSegmenterDecimalsAndListMarkers(t *testing.T) {
+	seg := NewDefaultSegmenter()
+
+	got := seg.Segment("Items: 1. First item 2. Second item costs 3.14 dollars. All done now.")
+	want := []string{
+		"Items: 1. First item 2. Second item costs 3.14 dollars.",
+		"All done now.",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Segment() = %#v, want %#v", got, want)
+	}
+}
+
+func TestRuleBasedSegmenterQuotedTerminator(t *testing.T) {
+	seg := NewDefaultSegmenter()
+
+	got := seg.Segment(`She said 'hello.' Then she left.`)
+	want := []string{
+		`She said 'hello.'`,
+		"Then she left.",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Segment() = %#v, want %#v", got, want)
+	}
+}
+
+func TestRuleBasedSegmenterEllipsis(t *testing.T) {
+	seg := NewDefaultSegmenter()
+
+	got := seg.Segment("Wait... what happened? I have no idea.")
+	want := []string{
+		"Wait... what happened?",
+		"I have no idea.",
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Segment() = %#v, want %#v", got, want)
+	}
+}
+
+func TestAggressiveSegmenterSplitsOnAbbreviations(t *testing.T) {
+	seg := NewAggressiveSegmenter()
+
+	got := seg.Segment("Dr. Smith left.")
+	want := []string{"Dr.", "Smith left."}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Segment() = %#v, want %#v", got, want)
+	}
+}
+
+func TestSegmenterForName(t *testing.T) {
+	if _, ok := SegmenterForName("aggressive").(*RuleBasedSegmenter); !ok {
+		t.Errorf("SegmenterForName(\"aggressive\") did not return a *RuleBasedSegmenter")
+	}
+	if !SegmenterForName("aggressive").(*RuleBasedSegmenter).Aggressive {
+		t.Errorf("SegmenterForName(\"aggressive\") should be aggressive")
+	}
+	if SegmenterForName("unknown").(*RuleBasedSegmenter).Aggressive {
+		t.Errorf("SegmenterForName(\"unknown\") should fall back to the default (non-aggressive) segmenter")
+	}
+}
@@ -6,48 +6,27 @@ import (
 	tokenizer "github.com/sugarme/tokenizer"
 )
 
-// ExtractSentencesFromText splits text into sentences based on sentence boundaries
-// A sentence is text ending with . or ? or !
+// ExtractSentencesFromText splits text into sentences using the default
+// segmenter, then splits any oversized sentence so the DP chunker never sees
+// one that exceeds maxSize tokens.
 func (em *EmbeddingModel) ExtractSentencesFromText(text string, maxSize int) []*Sentence {
+	return em.ExtractSentencesFromTextWithSegmenter(text, maxSize, NewDefaultSegmenter())
+}
+
+// ExtractSentencesFromTextWithSegmenter is like ExtractSentencesFromText but
+// lets the caller choose the SentenceSegmenter (see segmenter.go) instead of
+// always using the default.
+func (em *EmbeddingModel) ExtractSentencesFromTextWithSegmenter(text string, maxSize int, segmenter SentenceSegmenter) []*Sentence {
 	if text == "" {
 		return []*Sentence{}
 	}
 
 	var sentences []*Sentence
-	var currentSentence strings.Builder
-
-	// Split text into words and punctuation
-	words := strings.Fields(text)
-
-	for _, word := range words {
-		if currentSentence.Len() > 0 {
-			currentSentence.WriteString(" ")
-		}
-		currentSentence.WriteString(word)
-
-		// Check if this word ends with . or ? or !
-		trimmed := strings.TrimSpace(word)
-		if strings.HasSuffix(trimmed, ".") || strings.HasSuffix(trimmed, "!") || strings.HasSuffix(trimmed, "?") {
-			sentenceText := currentSentence.String()
-
-			sentences = append(sentences, &Sentence{
-				Text:       sentenceText,
-				StartTime:  "",  // Not applicable for text input
-				Embedding:  nil, // Will be populated by embedding function
-				TokenCount: CountTokens(em.Tokenizer, sentenceText),
-			})
-
-			currentSentence.Reset()
-		}
-	}
-
-	// Add any remaining text as a sentence
-	if currentSentence.Len() > 0 {
-		sentenceText := currentSentence.String()
+	for _, sentenceText := range segmenter.Segment(text) {
 		sentences = append(sentences, &Sentence{
 			Text:       sentenceText,
-			StartTime:  "",
-			Embedding:  nil,
+			StartTime:  "",  // Not applicable for text input
+			Embedding:  nil, // Will be populated by embedding function
 			TokenCount: CountTokens(em.Tokenizer, sentenceText),
 		})
 	}
@@ -107,6 +86,21 @@ func (em *EmbeddingModel) ExtractSentencesFromText(text string, maxSize int) []*
 	return finalSentences
 }
 
+// ExtractSentencesFromFramesWithSegmenter is like ExtractSentencesFromFrames
+// but lets the caller choose the SentenceSegmenter (see segmenter.go)
+// instead of always using the default.
+func (em *EmbeddingModel) ExtractSentencesFromFramesWithSegmenter(frames []Frame, maxSize int, segmenter SentenceSegmenter) []*Sentence {
+	var sentences []*Sentence
+	for _, frame := range frames {
+		frameSentences := em.ExtractSentencesFromTextWithSegmenter(frame.Text, maxSize, segmenter)
+		for _, sent := range frameSentences {
+			sent.StartTime = frame.StartTime
+		}
+		sentences = append(sentences, frameSentences...)
+	}
+	return sentences
+}
+
 func CountTokens(tok *tokenizer.Tokenizer, text string) int {
 	encoding, err := tok.EncodeSingle(text)
 	if err != nil {
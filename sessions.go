@@ -0,0 +1,226 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultReapInterval is how often SessionStore sweeps for expired sessions.
+const defaultReapInterval = time.Minute
+
+// ErrTooManyOpenSessions is returned by SessionStore.Create when the store
+// already holds SessionConfig.MaxOpenSessions sessions.
+var ErrTooManyOpenSessions = errors.New("too many open upload sessions")
+
+// ErrSequenceConflict is returned by UploadSession.Append when a sequence
+// is re-sent with content that differs from what was already stored.
+var ErrSequenceConflict = errors.New("sequence already received with different content")
+
+// SessionConfig bounds how long an upload session may stay open, how much
+// text it may accumulate, and how many sessions may be open at once.
+type SessionConfig struct {
+	TTL             time.Duration
+	MaxBytes        int64
+	MaxOpenSessions int
+}
+
+// LoadSessionConfig loads upload session configuration from environment
+// variables. Falls back to defaults if not set.
+func LoadSessionConfig() SessionConfig {
+	ttl := 30 * time.Minute
+	maxBytes := int64(64 * 1024 * 1024) // 64MB
+	maxOpenSessions := 1000
+
+	if envVal := os.Getenv("SESSION_TTL_SECONDS"); envVal != "" {
+		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
+			ttl = time.Duration(val) * time.Second
+		}
+	}
+
+	if envVal := os.Getenv("SESSION_MAX_BYTES"); envVal != "" {
+		if val, err := strconv.ParseInt(envVal, 10, 64); err == nil && val > 0 {
+			maxBytes = val
+		}
+	}
+
+	if envVal := os.Getenv("SESSION_MAX_OPEN"); envVal != "" {
+		if val, err := strconv.Atoi(envVal); err == nil && val > 0 {
+			maxOpenSessions = val
+		}
+	}
+
+	return SessionConfig{TTL: ttl, MaxBytes: maxBytes, MaxOpenSessions: maxOpenSessions}
+}
+
+// UploadSession accumulates ordered text fragments for a single large
+// document until Complete is called, so clients can feed multi-megabyte
+// transcripts without hitting request-body limits or read timeouts.
+type UploadSession struct {
+	ID             string
+	ChunkingConfig ChunkingConfig
+	Segmenter      string
+	CreatedAt      time.Time
+
+	mu         sync.Mutex
+	fragments  map[int]string
+	totalBytes int64
+}
+
+// Append stores the fragment at sequence, or no-ops if that sequence was
+// already stored (idempotent replay of a retried append). Returns
+// ErrSequenceConflict (wrapped with the sequence number) if sequence was
+// already stored with different content, or a plain error if storing the
+// fragment would exceed the session's max size.
+func (s *UploadSession) Append(sequence int, text string, maxBytes int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.fragments[sequence]; ok {
+		if existing != text {
+			return fmt.Errorf("%w: sequence %d", ErrSequenceConflict, sequence)
+		}
+		return nil // idempotent replay
+	}
+
+	if maxBytes > 0 && s.totalBytes+int64(len(text)) > maxBytes {
+		return fmt.Errorf("session %s would exceed max size of %d bytes", s.ID, maxBytes)
+	}
+
+	s.fragments[sequence] = text
+	s.totalBytes += int64(len(text))
+	return nil
+}
+
+// Text concatenates all received fragments in sequence order.
+func (s *UploadSession) Text() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sequences := make([]int, 0, len(s.fragments))
+	for seq := range s.fragments {
+		sequences = append(sequences, seq)
+	}
+	sort.Ints(sequences)
+
+	var b strings.Builder
+	for _, seq := range sequences {
+		b.WriteString(s.fragments[seq])
+	}
+	return b.String()
+}
+
+// SessionStore tracks in-flight upload sessions, evicting any that have
+// exceeded their TTL. A background goroutine sweeps for expired sessions
+// periodically, so one that's created and then abandoned (never appended to,
+// completed, or looked up again) doesn't stay in memory past its TTL.
+type SessionStore struct {
+	config SessionConfig
+
+	mu       sync.Mutex
+	sessions map[string]*UploadSession
+}
+
+// NewSessionStore creates an empty SessionStore governed by config and
+// starts its background reaper goroutine.
+func NewSessionStore(config SessionConfig) *SessionStore {
+	store := &SessionStore{
+		config:   config,
+		sessions: make(map[string]*UploadSession),
+	}
+	go store.reapLoop(defaultReapInterval)
+	return store
+}
+
+// reapLoop periodically evicts expired sessions until the process exits.
+func (s *SessionStore) reapLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		s.reapExpired()
+	}
+}
+
+func (s *SessionStore) reapExpired() {
+	if s.config.TTL <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range s.sessions {
+		if now.Sub(session.CreatedAt) > s.config.TTL {
+			delete(s.sessions, id)
+		}
+	}
+}
+
+// Create starts a new upload session and returns it. Returns
+// ErrTooManyOpenSessions if the store already holds MaxOpenSessions sessions.
+func (s *SessionStore) Create(chunkingConfig ChunkingConfig, segmenter string) (*UploadSession, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	session := &UploadSession{
+		ID:             id,
+		ChunkingConfig: chunkingConfig,
+		Segmenter:      segmenter,
+		CreatedAt:      time.Now(),
+		fragments:      make(map[int]string),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.config.MaxOpenSessions > 0 && len(s.sessions) >= s.config.MaxOpenSessions {
+		return nil, ErrTooManyOpenSessions
+	}
+
+	s.sessions[id] = session
+	return session, nil
+}
+
+// Get returns the session for id, or ok=false if it doesn't exist or has
+// expired (in which case it is evicted).
+func (s *SessionStore) Get(id string) (*UploadSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, false
+	}
+
+	if s.config.TTL > 0 && time.Since(session.CreatedAt) > s.config.TTL {
+		delete(s.sessions, id)
+		return nil, false
+	}
+
+	return session, true
+}
+
+// Delete removes a session, e.g. once it has been completed.
+func (s *SessionStore) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
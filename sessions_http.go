@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// CreateSessionRequest is the body of POST /embed/session.
+type CreateSessionRequest struct {
+	ChunkingConfig *ChunkingConfig `json:"chunking_config,omitempty"`
+	Segmenter      string          `json:"segmenter,omitempty"`
+}
+
+// CreateSessionResponse is the body of the POST /embed/session response.
+type CreateSessionResponse struct {
+	SessionID      string         `json:"session_id"`
+	ChunkingConfig ChunkingConfig `json:"chunking_config"`
+}
+
+// AppendRequest is the body of POST /embed/session/{id}/append.
+type AppendRequest struct {
+	Sequence int    `json:"sequence"`
+	Text     string `json:"text"`
+}
+
+// handleCreateSession starts a new resumable upload session.
+func handleCreateSession(w http.ResponseWriter, r *http.Request, store *SessionStore) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req CreateSessionRequest
+	if r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err.Error() != "EOF" {
+			http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	chunkingConfig := DefaultChunkingConfig()
+	if req.ChunkingConfig != nil {
+		chunkingConfig = *req.ChunkingConfig
+	}
+
+	session, err := store.Create(chunkingConfig, req.Segmenter)
+	if err != nil {
+		if errors.Is(err, ErrTooManyOpenSessions) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Failed to create session: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CreateSessionResponse{
+		SessionID:      session.ID,
+		ChunkingConfig: session.ChunkingConfig,
+	})
+}
+
+// handleSessionSub dispatches POST /embed/session/{id}/append and
+// /embed/session/{id}/complete.
+func handleSessionSub(w http.ResponseWriter, r *http.Request, store *SessionStore, embeddingModel *EmbeddingModel, cache EmbeddingCache, modelID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/embed/session/")
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		http.Error(w, "Expected /embed/session/{id}/append or /complete", http.StatusNotFound)
+		return
+	}
+	sessionID, action := parts[0], parts[1]
+
+	session, ok := store.Get(sessionID)
+	if !ok {
+		http.Error(w, "Session not found or expired", http.StatusNotFound)
+		return
+	}
+
+	switch action {
+	case "append":
+		handleAppendSession(w, r, store, session)
+	case "complete":
+		handleCompleteSession(w, r, store, embeddingModel, cache, modelID, session)
+	default:
+		http.Error(w, fmt.Sprintf("Unknown session action %q", action), http.StatusNotFound)
+	}
+}
+
+// handleAppendSession stores one ordered fragment of a session's text.
+// Re-appending the same sequence with identical content is a no-op, so
+// clients can safely retry a dropped request.
+func handleAppendSession(w http.ResponseWriter, r *http.Request, store *SessionStore, session *UploadSession) {
+	var req AppendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("Invalid request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	if err := session.Append(req.Sequence, req.Text, store.config.MaxBytes); err != nil {
+		if errors.Is(err, ErrSequenceConflict) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleCompleteSession concatenates all received fragments in sequence
+// order, runs them through the same segmentation + embedding pipeline as
+// /embed, and streams or buffers the response depending on wantsStream.
+func handleCompleteSession(w http.ResponseWriter, r *http.Request, store *SessionStore, embeddingModel *EmbeddingModel, cache EmbeddingCache, modelID string, session *UploadSession) {
+	defer store.Delete(session.ID)
+
+	doc := EmbedRequest{
+		ID:             session.ID,
+		Text:           session.Text(),
+		ChunkingConfig: &session.ChunkingConfig,
+		Segmenter:      session.Segmenter,
+	}
+
+	if wantsStream(r) {
+		streamEmbed(w, r, embeddingModel, BatchEmbedRequest{Documents: []EmbedRequest{doc}}, cache, modelID)
+		return
+	}
+
+	docResp := processDocument(embeddingModel, &doc, cache, modelID, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(docResp); err != nil {
+		fmt.Printf("Failed to encode session completion response: %v\n", err)
+	}
+}